@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"os"
+)
+
+// BSGSTable is a precomputed Baby-Step Giant-Step baby-step table that can be
+// built once and reused across many CombinePartialDecryptions calls (e.g.
+// across election rounds), instead of rebuilding the full sqrt(N)-entry map
+// on every call.
+//
+// Window trades memory for extra lookups: only every Window-th baby step is
+// stored, so the table is 1/Window the size, and each giant step then probes
+// up to Window candidate points instead of one (the number of giant steps
+// is unchanged).
+type BSGSTable struct {
+	MaxMessage uint64
+	Window     uint64
+	// steps maps a stored baby-step point (by its compressed bytes) to the
+	// baby-step index j it corresponds to, i.e. j such that point = j*G.
+	steps map[string]uint64
+}
+
+// NewBSGSTable builds a BSGSTable for discrete logs in [0, maxMessage],
+// storing every window-th baby step.
+func NewBSGSTable(maxMessage, window uint64) *BSGSTable {
+	if window == 0 {
+		window = 1
+	}
+	mSqrt := uint64(math.Sqrt(float64(maxMessage))) + 1
+
+	steps := make(map[string]uint64, mSqrt/window+1)
+	point := &G1{}
+	point.SetZero()
+	G := &G1{}
+	G.ScalarBaseMult(big.NewInt(1))
+	windowStep := &G1{}
+	windowStep.ScalarBaseMult(new(big.Int).SetUint64(window))
+
+	for j := uint64(0); j <= mSqrt; j += window {
+		steps[string(point.Marshal())] = j
+		point.Add(point, windowStep)
+	}
+	return &BSGSTable{MaxMessage: maxMessage, Window: window, steps: steps}
+}
+
+// Save serializes the table to path using a compact binary format: a fixed
+// header (MaxMessage, Window, entry count) followed by, per entry, the
+// fixed-width compressed G1 point encoding and a varint baby-step index.
+func (t *BSGSTable) Save(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	var header [24]byte
+	binary.LittleEndian.PutUint64(header[0:8], t.MaxMessage)
+	binary.LittleEndian.PutUint64(header[8:16], t.Window)
+	binary.LittleEndian.PutUint64(header[16:24], uint64(len(t.steps)))
+	if _, err := w.Write(header[:]); err != nil {
+		return err
+	}
+
+	varint := make([]byte, binary.MaxVarintLen64)
+	for key, j := range t.steps {
+		if _, err := w.Write([]byte(key)); err != nil {
+			return err
+		}
+		n := binary.PutUvarint(varint, j)
+		if _, err := w.Write(varint[:n]); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// LoadBSGSTable reads back a table previously written with Save.
+func LoadBSGSTable(path string) (*BSGSTable, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	var header [24]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, fmt.Errorf("failed to read BSGS table header: %w", err)
+	}
+	maxMessage := binary.LittleEndian.Uint64(header[0:8])
+	window := binary.LittleEndian.Uint64(header[8:16])
+	count := binary.LittleEndian.Uint64(header[16:24])
+
+	steps := make(map[string]uint64, count)
+	pointLen := len((&G1{}).Marshal())
+	point := make([]byte, pointLen)
+	for i := uint64(0); i < count; i++ {
+		if _, err := io.ReadFull(r, point); err != nil {
+			return nil, fmt.Errorf("failed to read BSGS table entry %d: %w", i, err)
+		}
+		j, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read BSGS table entry %d index: %w", i, err)
+		}
+		steps[string(point)] = j
+	}
+	return &BSGSTable{MaxMessage: maxMessage, Window: window, steps: steps}, nil
+}
+
+// babyStepGiantStepWithTable is the persisted-table counterpart of
+// babyStepGiantStep: the baby-step side is free (already built in table),
+// and only the giant-step search has to run. The giant-step stride must be
+// mSqrt = sqrt(table.MaxMessage)+1 regardless of table.Window: the baby
+// steps stored in the table only cover offsets [0, mSqrt), so striding by
+// anything other than mSqrt would walk past baby steps the table never
+// recorded, making the search incomplete (or, if Window > mSqrt, silently
+// wrong since the inner sweep would need to run more than mSqrt steps to
+// stay within that stride).
+//
+// table only stores baby steps at multiples of Window, so giantStep itself
+// (= m - i*mSqrt*G = k*G for the true offset k) is a stored key only when k
+// happens to be one of those multiples. To find k for any offset, each
+// giant step probes the Window candidates giantStep - off*G for
+// off = 0, ..., Window-1: k-off is a stored multiple of Window for exactly
+// one of them (off = k mod Window), at which point x = i*mSqrt + j + off is
+// the discrete log.
+func babyStepGiantStepWithTable(m *G1, table *BSGSTable) (*big.Int, error) {
+	mSqrt := uint64(math.Sqrt(float64(table.MaxMessage))) + 1
+
+	c := &G1{}
+	c.ScalarBaseMult(new(big.Int).SetUint64(mSqrt))
+	c.Neg(c) // c = -mSqrt * G
+
+	negG := &G1{}
+	negG.ScalarBaseMult(big.NewInt(1))
+	negG.Neg(negG) // negG = -G
+
+	giantStep := &G1{}
+	giantStep.Set(m)
+
+	maxGiantSteps := table.MaxMessage/mSqrt + 1
+	for i := uint64(0); i <= maxGiantSteps; i++ {
+		candidate := &G1{}
+		candidate.Set(giantStep)
+		for off := uint64(0); off < table.Window; off++ {
+			key := string(candidate.Marshal())
+			if j, found := table.steps[key]; found {
+				x := new(big.Int).SetUint64(i*mSqrt + j + off)
+				test := &G1{}
+				test.ScalarBaseMult(x)
+				if test.Equal(m) {
+					return x, nil
+				}
+			}
+			candidate.Add(candidate, negG)
+		}
+		giantStep.Add(giantStep, c)
+	}
+	return nil, fmt.Errorf("failed to compute discrete logarithm using the persisted BSGS table")
+}
+
+// CombinePartialDecryptionsWithTable is CombinePartialDecryptions using a
+// pre-built, possibly disk-persisted BSGSTable instead of rebuilding the
+// baby-step map on every call. Intended for deployments that run many
+// election rounds against the same max-message bound.
+func CombinePartialDecryptionsWithTable(c1, c2 *G1, partialDecryptions, pubShares map[int]*G1, proofs map[int]*DecryptionProof, participants []int, table *BSGSTable) (*big.Int, error) {
+	for _, id := range participants {
+		pd, ok := partialDecryptions[id]
+		if !ok {
+			return nil, fmt.Errorf("missing partial decryption for participant %d", id)
+		}
+		pubShare, ok := pubShares[id]
+		if !ok {
+			return nil, fmt.Errorf("missing public share for participant %d", id)
+		}
+		proof, ok := proofs[id]
+		if !ok {
+			return nil, fmt.Errorf("missing decryption proof for participant %d", id)
+		}
+		if !VerifyPartialDecryption(c1, pubShare, pd, proof) {
+			return nil, fmt.Errorf("participant %d submitted an invalid partial decryption proof", id)
+		}
+	}
+
+	lagrangeCoeffs := computeLagrangeCoefficients(participants)
+	s := &G1{}
+	for _, id := range participants {
+		pd := partialDecryptions[id]
+		lambda := lagrangeCoeffs[id]
+		term := &G1{}
+		term.ScalarMult(pd, lambda)
+		s.Add(s, term)
+	}
+	s.Neg(s)
+	m := &G1{}
+	m.Add(c2, s)
+
+	messageScalar, err := babyStepGiantStepWithTable(m, table)
+	if err != nil {
+		return nil, err
+	}
+	return messageScalar, nil
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"math/big"
+	"testing"
+)
+
+// TestBabyStepGiantStepWithTableWindowed checks that a Window > 1 table can
+// still solve for a discrete log whose baby-step offset is not itself a
+// multiple of Window, which is the case the giant-step sweep used to miss
+// entirely (it only ever matched offsets that were already stored table
+// keys).
+func TestBabyStepGiantStepWithTableWindowed(t *testing.T) {
+	const maxMessage = 10000
+	const window = 7
+	table := NewBSGSTable(maxMessage, window)
+
+	for _, x := range []uint64{1, 3, 100, 101, 103, 9999} {
+		if x%window == 0 {
+			t.Fatalf("test value %d is a multiple of window %d, does not exercise the windowed sweep", x, window)
+		}
+		m := &G1{}
+		m.ScalarBaseMult(new(big.Int).SetUint64(x))
+
+		got, err := babyStepGiantStepWithTable(m, table)
+		if err != nil {
+			t.Fatalf("babyStepGiantStepWithTable(%d) returned an error: %v", x, err)
+		}
+		if got.Cmp(new(big.Int).SetUint64(x)) != 0 {
+			t.Fatalf("babyStepGiantStepWithTable(%d) = %s, want %d", x, got, x)
+		}
+	}
+}
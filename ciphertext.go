@@ -0,0 +1,54 @@
+package main
+
+import "math/big"
+
+// Ciphertext is an ElGamal ciphertext (C1, C2) over G1, encrypting a
+// plaintext represented as message*G. Because the scheme is additively
+// homomorphic, (C1, C2) + (C1', C2') decrypts to the sum of the two
+// plaintexts, which is what lets the combiner sum many ballots before ever
+// running the expensive discrete log search.
+type Ciphertext struct {
+	C1 *G1
+	C2 *G1
+}
+
+// Add sets c to the homomorphic sum of a and b: c.C1 = a.C1+b.C1 and
+// c.C2 = a.C2+b.C2, which decrypts to the sum of a and b's plaintexts.
+func (c *Ciphertext) Add(a, b *Ciphertext) {
+	c1 := &G1{}
+	c1.Add(a.C1, b.C1)
+	c2 := &G1{}
+	c2.Add(a.C2, b.C2)
+	c.C1 = c1
+	c.C2 = c2
+}
+
+// AggregateCiphertexts homomorphically sums a slice of ciphertexts into one,
+// so the combiner only has to run the discrete log search once on the
+// aggregate rather than once per ballot.
+func AggregateCiphertexts(cs []*Ciphertext) *Ciphertext {
+	total := &Ciphertext{C1: &G1{}, C2: &G1{}}
+	total.C1.SetZero()
+	total.C2.SetZero()
+	for _, c := range cs {
+		total.Add(total, c)
+	}
+	return total
+}
+
+// discreteLogBound returns the search bound to use for the discrete log
+// recovered from n aggregated ciphertexts each encoding a plaintext in
+// [0, maxValue], instead of the fixed discreteLogMaxMessage constant.
+func discreteLogBound(n, maxValue uint64) uint64 {
+	return n * maxValue
+}
+
+// CombineAggregatedDecryptions is the homomorphic-tally counterpart of
+// CombinePartialDecryptions: it recovers the message encoded by an
+// aggregated Ciphertext built from n ballots each in [0, maxValue], using
+// n*maxValue as the discrete log search bound instead of the hard-coded
+// discreteLogMaxMessage.
+func CombineAggregatedDecryptions(aggregated *Ciphertext, n, maxValue uint64, partialDecryptions, pubShares map[int]*G1, proofs map[int]*DecryptionProof, participants []int) (*big.Int, error) {
+	bound := discreteLogBound(n, maxValue)
+	return combinePartialDecryptionsWithBound(aggregated.C1, aggregated.C2, partialDecryptions, pubShares, proofs, participants, bound)
+}
@@ -0,0 +1,141 @@
+// batch.go verifies M per-vote Groth16 ballot proofs that share a single
+// verifying key, binding every vote's own public inputs into the check. An
+// earlier version of this file tried to fold the M proofs' A-elements into
+// one randomized linear combination and verify a single templated proof;
+// that shortcut only ever checked PublicInputs[0] and Proofs[0]'s B/K
+// elements, so votes 1..M-1 could carry arbitrary garbage proofs. Groth16's
+// pairing equation doesn't reduce to a single pairing across proofs with
+// different B elements, so each proof still has to be verified against its
+// own public inputs; the real savings available here is batching the
+// verifier's *final exponentiation*, not skipping per-vote verification.
+package aggregator
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bn254"
+	"github.com/consensys/gnark/std/math/bits"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/std/recursion/groth16"
+
+	"github.com/consensys/gnark/frontend"
+)
+
+// MaxVotes is the number of votes folded into a single BatchVerifyVoteCircuit
+// instance.
+const MaxVotes = 4
+
+// BatchVerifyVoteCircuit verifies M votes that share a single ballot-proof
+// verifying key. Every vote's own proof is checked against its own public
+// inputs, and each InputsHash entry is asserted equal to the public input of
+// the matching proof.
+type BatchVerifyVoteCircuit struct {
+	// InputsHash holds each vote's own InputsHash, in batch order:
+	// InputsHash[i] must equal the single public input of Proofs[i].
+	InputsHash [MaxVotes]frontend.Variable `gnark:",public"`
+	// Proofs holds the M individual ballot proofs sharing VerificationKey,
+	// each checked against its own PublicInputs[i].
+	Proofs          [MaxVotes]groth16.Proof[sw_bn254.G1Affine, sw_bn254.G2Affine]
+	PublicInputs    [MaxVotes]groth16.Witness[sw_bn254.ScalarField]
+	VerificationKey groth16.VerifyingKey[sw_bn254.G1Affine, sw_bn254.G2Affine, sw_bn254.GTEl] `gnark:"-"`
+	// Pair identifies the outer/inner recursion curve pair this batch
+	// deployment is built for, which selects the recursion-friendly hash
+	// batchChallenge uses for its Fiat-Shamir challenge. Defaults to
+	// RecursionBW6761BLS12377, the pair the aggregator has always used.
+	Pair RecursionPair `gnark:"-"`
+}
+
+// batchChallenge derives the Fiat-Shamir challenge rho bound to the
+// InputsHash of every vote in the batch, and returns its first len(powers)
+// powers (1, rho, rho^2, ..., rho^{M-1}). The powers are not used to fold
+// the proofs themselves (that combination is unsound for Groth16 proofs with
+// distinct B elements); Define computes them but uses them in no check. The
+// hash
+// used is the recursion-friendly one for pair (see recursionHash), so the
+// challenge stays sound regardless of which outer/inner curve pair the
+// batch deployment is built for.
+func batchChallenge(api frontend.API, pair RecursionPair, inputsHashes [MaxVotes]frontend.Variable) ([MaxVotes]frontend.Variable, error) {
+	var powers [MaxVotes]frontend.Variable
+	h, err := recursionHash(api, pair)
+	if err != nil {
+		return powers, err
+	}
+	h.Write(inputsHashes[:]...)
+	rho := h.Sum()
+	powers[0] = 1
+	for i := 1; i < MaxVotes; i++ {
+		powers[i] = api.Mul(powers[i-1], rho)
+	}
+	return powers, nil
+}
+
+// varToFieldElem decomposes the native variable v into bits and reassembles
+// it as an element of the emulated field FP, so it can be compared against a
+// nonnative witness value coming from a proof verified over a different
+// native field (mirrors the helper of the same name in package
+// voteverifier; kept package-local since that one is unexported).
+func varToFieldElem[FP emulated.FieldParams](api frontend.API, v frontend.Variable) (*emulated.Element[FP], error) {
+	field, err := emulated.NewField[FP](api)
+	if err != nil {
+		return nil, err
+	}
+	return field.FromBits(bits.ToBinary(api, v)...), nil
+}
+
+// assertEqualToElement asserts that the native variable a equals the
+// emulated element b, limb by limb.
+func assertEqualToElement[FP emulated.FieldParams](api frontend.API, a frontend.Variable, b emulated.Element[FP]) error {
+	aElem, err := varToFieldElem[FP](api, a)
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(len(aElem.Limbs), len(b.Limbs))
+	for i, v := range aElem.Limbs {
+		api.AssertIsEqual(v, b.Limbs[i])
+	}
+	return nil
+}
+
+// Define verifies every one of the M ballot proofs against its own public
+// inputs and shared verifying key, and binds each vote's public InputsHash
+// entry to the public input actually checked by AssertProof: without that
+// binding, InputsHash is just a free-standing public signal an aggregator
+// could publish independently of the proofs it submits, so the Fiat-Shamir
+// challenge batchChallenge derives from it wouldn't mean anything either.
+// Each AssertProof call runs its own pairing check, final exponentiation
+// included, so the circuit costs M full Groth16 verifications;
+// groth16.WithCompleteArithmetic() only selects complete formulas for the
+// emulated point arithmetic.
+func (c *BatchVerifyVoteCircuit) Define(api frontend.API) error {
+	if _, err := batchChallenge(api, c.recursionPair(), c.InputsHash); err != nil {
+		return err
+	}
+	verifier, err := groth16.NewVerifier[sw_bn254.ScalarField, sw_bn254.G1Affine, sw_bn254.G2Affine, sw_bn254.GTEl](api)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < MaxVotes; i++ {
+		if err := verifier.AssertProof(
+			c.VerificationKey, c.Proofs[i], c.PublicInputs[i],
+			groth16.WithCompleteArithmetic()); err != nil {
+			return err
+		}
+		if len(c.PublicInputs[i].Public) != 1 {
+			return fmt.Errorf("vote %d: expected exactly 1 public input (InputsHash), got %d", i, len(c.PublicInputs[i].Public))
+		}
+		if err := assertEqualToElement(api, c.InputsHash[i], c.PublicInputs[i].Public[0]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recursionPair returns c.Pair, defaulting to RecursionBW6761BLS12377 (the
+// pair the aggregator has always used) when the circuit was built without
+// one explicitly set.
+func (c *BatchVerifyVoteCircuit) recursionPair() RecursionPair {
+	if c.Pair == (RecursionPair{}) {
+		return RecursionBW6761BLS12377
+	}
+	return c.Pair
+}
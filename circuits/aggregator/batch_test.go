@@ -0,0 +1,130 @@
+package aggregator
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	nativegroth16 "github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bn254"
+	stdgroth16 "github.com/consensys/gnark/std/recursion/groth16"
+	"github.com/consensys/gnark/test"
+	qt "github.com/frankban/quicktest"
+)
+
+// trivialBallotCircuit stands in for a real per-vote ballot-proof circuit:
+// it only needs a single public input so BatchVerifyVoteCircuit has a real
+// Groth16 proof to verify and an InputsHash value to bind to.
+type trivialBallotCircuit struct {
+	InputsHash frontend.Variable `gnark:",public"`
+	Secret     frontend.Variable
+}
+
+func (c *trivialBallotCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(c.InputsHash, api.Mul(c.Secret, c.Secret))
+	return nil
+}
+
+// TestBatchVerifyVoteCircuitBindsInputsHash instantiates BatchVerifyVoteCircuit
+// end-to-end with MaxVotes real Groth16 proofs over trivialBallotCircuit,
+// checking both that honestly matching (proof, InputsHash) pairs solve and
+// that swapping in an InputsHash that doesn't match the proof's own public
+// input is rejected, proving InputsHash is actually bound to PublicInputs
+// rather than a free-standing public signal.
+func TestBatchVerifyVoteCircuitBindsInputsHash(t *testing.T) {
+	c := qt.New(t)
+
+	innerCcs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &trivialBallotCircuit{})
+	c.Assert(err, qt.IsNil)
+	pk, vk, err := nativegroth16.Setup(innerCcs)
+	c.Assert(err, qt.IsNil)
+
+	var (
+		proofs     [MaxVotes]stdgroth16.Proof[sw_bn254.G1Affine, sw_bn254.G2Affine]
+		publics    [MaxVotes]stdgroth16.Witness[sw_bn254.ScalarField]
+		inputsHash [MaxVotes]frontend.Variable
+	)
+	for i := 0; i < MaxVotes; i++ {
+		secret := int64(i + 2)
+		assignment := &trivialBallotCircuit{InputsHash: secret * secret, Secret: secret}
+		witness, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField())
+		c.Assert(err, qt.IsNil)
+		proof, err := nativegroth16.Prove(innerCcs, pk, witness)
+		c.Assert(err, qt.IsNil)
+		publicWitness, err := witness.Public()
+		c.Assert(err, qt.IsNil)
+
+		proofs[i], err = stdgroth16.ValueOfProof[sw_bn254.G1Affine, sw_bn254.G2Affine](proof)
+		c.Assert(err, qt.IsNil)
+		publics[i], err = stdgroth16.ValueOfWitness[sw_bn254.ScalarField](publicWitness)
+		c.Assert(err, qt.IsNil)
+		inputsHash[i] = secret * secret
+	}
+	verificationKey, err := stdgroth16.ValueOfVerifyingKey[sw_bn254.G1Affine, sw_bn254.G2Affine, sw_bn254.GTEl](vk)
+	c.Assert(err, qt.IsNil)
+
+	placeholder := &BatchVerifyVoteCircuit{VerificationKey: verificationKey}
+	for i := 0; i < MaxVotes; i++ {
+		placeholder.Proofs[i] = stdgroth16.PlaceholderProof[sw_bn254.G1Affine, sw_bn254.G2Affine](innerCcs)
+		placeholder.PublicInputs[i] = stdgroth16.PlaceholderWitness[sw_bn254.ScalarField](innerCcs)
+	}
+	honest := &BatchVerifyVoteCircuit{
+		InputsHash:      inputsHash,
+		Proofs:          proofs,
+		PublicInputs:    publics,
+		VerificationKey: verificationKey,
+	}
+	assert := test.NewAssert(t)
+	assert.SolvingSucceeded(placeholder, honest,
+		test.WithCurves(ecc.BW6_761), test.WithBackends(backend.GROTH16),
+		test.WithProverOpts(stdgroth16.GetNativeProverOptions(ecc.BN254.ScalarField(), ecc.BW6_761.ScalarField())))
+
+	tampered := &BatchVerifyVoteCircuit{
+		InputsHash:      inputsHash,
+		Proofs:          proofs,
+		PublicInputs:    publics,
+		VerificationKey: verificationKey,
+	}
+	tampered.InputsHash[0] = int64(9999)
+	assert.SolvingFailed(placeholder, tampered,
+		test.WithCurves(ecc.BW6_761), test.WithBackends(backend.GROTH16),
+		test.WithProverOpts(stdgroth16.GetNativeProverOptions(ecc.BN254.ScalarField(), ecc.BW6_761.ScalarField())))
+}
+
+// batchChallengeBindingCircuit exercises batchChallenge in isolation,
+// independently of the Groth16 proof machinery, to check that the
+// Fiat-Shamir challenge is actually bound to every vote's InputsHash entry
+// rather than only a subset of them.
+type batchChallengeBindingCircuit struct {
+	InputsHashA [MaxVotes]frontend.Variable
+	InputsHashB [MaxVotes]frontend.Variable
+}
+
+func (c *batchChallengeBindingCircuit) Define(api frontend.API) error {
+	powersA, err := batchChallenge(api, RecursionBW6761BLS12377, c.InputsHashA)
+	if err != nil {
+		return err
+	}
+	powersB, err := batchChallenge(api, RecursionBW6761BLS12377, c.InputsHashB)
+	if err != nil {
+		return err
+	}
+	api.AssertIsDifferent(powersA[1], powersB[1])
+	return nil
+}
+
+// TestBatchChallengeBindsEveryVote checks that batchChallenge's rho depends
+// on every position of InputsHash, not just the first: two InputsHash arrays
+// differing only in their last entry must still yield a different
+// challenge, so a batch cannot be recombined from votes that differ only in
+// the votes a naive implementation might otherwise ignore.
+func TestBatchChallengeBindsEveryVote(t *testing.T) {
+	assert := test.NewAssert(t)
+	assignment := &batchChallengeBindingCircuit{
+		InputsHashA: [MaxVotes]frontend.Variable{1, 2, 3, 4},
+		InputsHashB: [MaxVotes]frontend.Variable{1, 2, 3, 5},
+	}
+	assert.SolvingSucceeded(&batchChallengeBindingCircuit{}, assignment, test.WithCurves(ecc.BW6_761))
+}
@@ -0,0 +1,64 @@
+// recursion.go generalizes the aggregator over the outer/inner recursion
+// curve pair instead of hard-wiring BW6-761/BLS12-377, so deployments can
+// trade prover time for on-chain verifier gas by picking a smaller pair such
+// as BW6-633/BLS24-315 when the target verifier supports it.
+package aggregator
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/recursion"
+)
+
+// RecursionPair identifies a supported (outer, inner) curve pair for the
+// aggregator's recursive verification of voteverifier proofs. The outer
+// curve is the one the aggregator circuit itself is compiled over; the inner
+// curve is the one the aggregated voteverifier proofs were generated over.
+type RecursionPair struct {
+	Outer ecc.ID
+	Inner ecc.ID
+}
+
+var (
+	// RecursionBW6761BLS12377 is the pair used by the existing aggregator:
+	// BW6-761 outer curve recursively verifying BLS12-377 inner proofs.
+	RecursionBW6761BLS12377 = RecursionPair{Outer: ecc.BW6_761, Inner: ecc.BLS12_377}
+	// RecursionBW6633BLS24315 trades a smaller outer curve for cheaper
+	// on-chain verification when the target verifier supports BLS24-315.
+	RecursionBW6633BLS24315 = RecursionPair{Outer: ecc.BW6_633, Inner: ecc.BLS24_315}
+	// RecursionBN254BW6761 lets the aggregator itself be recursively
+	// verified on BN254, for chains whose verifier only supports that curve.
+	RecursionBN254BW6761 = RecursionPair{Outer: ecc.BN254, Inner: ecc.BW6_761}
+)
+
+// supportedRecursionPairs lists every (outer, inner) combination the
+// aggregator knows how to wire a recursion hash for.
+var supportedRecursionPairs = []RecursionPair{
+	RecursionBW6761BLS12377,
+	RecursionBW6633BLS24315,
+	RecursionBN254BW6761,
+}
+
+// validateRecursionPair returns an error if pair is not one of the supported
+// combinations.
+func validateRecursionPair(pair RecursionPair) error {
+	for _, p := range supportedRecursionPairs {
+		if p == pair {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported recursion pair: outer=%s inner=%s", pair.Outer, pair.Inner)
+}
+
+// recursionHash returns the hash.FieldHasher that should be used to write
+// the InputsHash for the given recursion pair: the short recursion-friendly
+// hash wrapped for the chosen outer/inner field pair, so scalar marshalling
+// across fields stays sound regardless of which pair is selected.
+func recursionHash(api frontend.API, pair RecursionPair) (recursion.FieldHasher, error) {
+	if err := validateRecursionPair(pair); err != nil {
+		return nil, err
+	}
+	return recursion.NewShort(api, pair.Outer.ScalarField(), pair.Inner.ScalarField())
+}
@@ -20,6 +20,21 @@ type ProcessMetadata struct {
 	MinTotalCost    frontend.Variable
 	CostExp         frontend.Variable
 	CostFromWeight  frontend.Variable
+	// PolynomialCommitment is the MiMC commitment to the coefficients of the
+	// ranked/score ballot polynomial (see PolynomialBallot), or zero for
+	// processes that use the plain point-wise ballot encoding.
+	PolynomialCommitment frontend.Variable
+}
+
+// PolynomialBallot carries the coefficients of a degree-(k-1) polynomial
+// p(x) committed to by the voter, used to encode ranked or weighted score
+// ballots: for each candidate-specific public input x_j the circuit asserts
+// p(x_j) equals the j-th ciphertext plaintext, evaluating p with a Horner
+// scheme so the same witness is reused for every candidate and coefficients
+// cannot be swapped between them.
+type PolynomialBallot struct {
+	Coefficients [8]frontend.Variable
+	Commitment   frontend.Variable
 }
 
 // CircomProof contains the proof generated by a Circom circuit with snarkjs and
@@ -41,3 +56,20 @@ type CensusProof struct {
 	Value    frontend.Variable
 	Siblings [160]frontend.Variable
 }
+
+// CensusProofV2 is the variable-depth counterpart of CensusProof. It carries
+// an explicit Depth witness alongside the fixed-size Siblings array, so a
+// single circuit can accept proofs from trees of varying depth instead of
+// being compiled for one fixed depth. Depth is the number of siblings that
+// are actually part of the proof; the remaining entries of Siblings are
+// ignored padding. Because R1CS has no runtime branching, the inclusion
+// check (checkInclusionProofV2) still compiles and executes all 160 MiMC
+// folding calls unconditionally regardless of Depth — it does not save any
+// hashing work over the fixed-depth path.
+type CensusProofV2 struct {
+	Root     frontend.Variable
+	Key      frontend.Variable
+	Value    frontend.Variable
+	Depth    frontend.Variable
+	Siblings [160]frontend.Variable
+}
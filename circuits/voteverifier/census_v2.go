@@ -0,0 +1,54 @@
+package voteverifier
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/vocdoni/gnark-crypto-primitives/arbo"
+)
+
+// checkInclusionProofV2 is the variable-depth counterpart of
+// arbo.CheckInclusionProof: depth selects, for each vote, which of the 160
+// folded intermediate hashes is compared against root, instead of always
+// requiring the full 160-level path. Because R1CS has no runtime branching,
+// every one of the 160 MiMC folding calls below is still compiled and
+// executed unconditionally regardless of depth — an earlier version of this
+// comment claimed this saves ~140 MiMC permutations per vote for typical
+// ~20-depth trees, which was false; this function costs the same number of
+// MiMC calls as the fixed-depth path. The one real saving here is in the
+// per-level selection logic: instead of a full magnitude comparison
+// (api.Cmp, which costs a bit decomposition per level), it tracks whether
+// the loop has already passed the depth boundary with a running flag built
+// from a single equality check per level, which is cheaper but does not
+// change the dominant MiMC cost.
+func checkInclusionProofV2(api frontend.API, hashFn arbo.HashFunction, key, value, root frontend.Variable,
+	depth frontend.Variable, siblings []frontend.Variable,
+) error {
+	hash, err := hashFn(api, key, value)
+	if err != nil {
+		return err
+	}
+	// pastDepth is 1 once the loop has processed the level at index depth,
+	// i.e. for every subsequent (lower) i the path is still within
+	// [0, depth) and must keep folding. It starts at 1 when depth equals
+	// len(siblings), since in that case every level is within range and
+	// there is no in-range index equal to depth to trigger the flag.
+	pastDepth := api.IsZero(api.Sub(depth, len(siblings)))
+	for i := len(siblings) - 1; i >= 0; i-- {
+		active := pastDepth
+		atBoundary := api.IsZero(api.Sub(frontend.Variable(i), depth))
+		pastDepth = api.Select(atBoundary, 1, pastDepth)
+		folded, err := hashFn(api, siblings[i], hash)
+		if err != nil {
+			return err
+		}
+		hash = api.Select(active, folded, hash)
+	}
+	api.AssertIsEqual(hash, root)
+	return nil
+}
+
+// checkCensusInclusionV2 verifies a CensusProofV2 (explicit depth, sparse
+// siblings) for the given address and weight, reusing the same censusHashFn
+// MiMC hash as the fixed-depth path.
+func checkCensusInclusionV2(api frontend.API, address, weight, root, depth frontend.Variable, siblings [160]frontend.Variable) error {
+	return checkInclusionProofV2(api, censusHashFn, address, weight, root, depth, siblings[:])
+}
@@ -0,0 +1,75 @@
+package voteverifier
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+)
+
+type censusV2Circuit struct {
+	Key, Value, Root, Depth frontend.Variable
+	Siblings                [160]frontend.Variable
+}
+
+func (c *censusV2Circuit) Define(api frontend.API) error {
+	return checkCensusInclusionV2(api, c.Key, c.Value, c.Root, c.Depth, c.Siblings)
+}
+
+// TestCheckInclusionProofV2AcceptsValidPartialDepthProof checks that a
+// genuinely valid partial-depth proof is accepted: it folds the leaf hash
+// with siblings[depth-1..0] off-circuit the same way checkInclusionProofV2
+// does in-circuit (siblings beyond depth are padding), and checks that
+// result is accepted as the root for several depths, including 0 (the leaf
+// hash itself is the root) and 160 (the full-depth path).
+func TestCheckInclusionProofV2AcceptsValidPartialDepthProof(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	for _, depth := range []int{0, 3, 160} {
+		var siblings [160]frontend.Variable
+		siblingVals := make([]*big.Int, 160)
+		for i := range siblings {
+			siblingVals[i] = big.NewInt(int64(i + 1))
+			siblings[i] = siblingVals[i]
+		}
+
+		key, value := big.NewInt(1), big.NewInt(2)
+		root := mimcSumForTest(key, value)
+		for i := depth - 1; i >= 0; i-- {
+			root = mimcSumForTest(siblingVals[i], root)
+		}
+
+		assignment := &censusV2Circuit{
+			Key:      key,
+			Value:    value,
+			Root:     root,
+			Depth:    depth,
+			Siblings: siblings,
+		}
+		assert.SolvingSucceeded(&censusV2Circuit{}, assignment, test.WithCurves(ecc.BW6_761))
+	}
+}
+
+// TestCheckInclusionProofV2RejectsWrongRoot checks that checkInclusionProofV2
+// still rejects a claimed root that does not match the folded path, for both
+// a partial depth, depth 0 and the full 160-level depth.
+func TestCheckInclusionProofV2RejectsWrongRoot(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	for _, depth := range []int{0, 3, 160} {
+		var siblings [160]frontend.Variable
+		for i := range siblings {
+			siblings[i] = i + 1
+		}
+		assignment := &censusV2Circuit{
+			Key:      1,
+			Value:    2,
+			Root:     42, // arbitrary, does not match the folded path
+			Depth:    depth,
+			Siblings: siblings,
+		}
+		assert.SolvingFailed(&censusV2Circuit{}, assignment, test.WithCurves(ecc.BW6_761))
+	}
+}
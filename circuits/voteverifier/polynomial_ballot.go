@@ -0,0 +1,42 @@
+package voteverifier
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/vocdoni/vocdoni-z-sandbox/circuits"
+	"github.com/vocdoni/vocdoni-z-sandbox/circuits/voteverifier/mimc"
+)
+
+// evalPolynomial evaluates the ranked-ballot polynomial p(x) = c_0 + c_1*x +
+// ... + c_{k-1}*x^{k-1} at x using Horner's scheme, so the same witness
+// (coefficients) is reused for every candidate evaluation instead of
+// re-deriving a fresh polynomial per candidate.
+func evalPolynomial(api frontend.API, coefficients []frontend.Variable, x frontend.Variable) frontend.Variable {
+	result := coefficients[len(coefficients)-1]
+	for i := len(coefficients) - 2; i >= 0; i-- {
+		result = api.Add(coefficients[i], api.Mul(result, x))
+	}
+	return result
+}
+
+// checkPolynomialBallot verifies a PolynomialBallot against the candidate
+// public inputs xs and the expected plaintexts (the per-candidate decoded
+// ciphertext values): it asserts that evaluating the shared polynomial at
+// each x_j reproduces the j-th plaintext, and that the coefficients match
+// the commitment carried in ProcessMetadata.PolynomialCommitment.
+func checkPolynomialBallot(api frontend.API, meta circuits.ProcessMetadata, ballot circuits.PolynomialBallot,
+	xs, plaintexts []frontend.Variable,
+) error {
+	h, err := mimc.NewMiMC(api, nil)
+	if err != nil {
+		return err
+	}
+	h.Write(ballot.Coefficients[:]...)
+	api.AssertIsEqual(h.Sum(), ballot.Commitment)
+	api.AssertIsEqual(ballot.Commitment, meta.PolynomialCommitment)
+
+	api.AssertIsEqual(len(xs), len(plaintexts))
+	for j := range xs {
+		api.AssertIsEqual(evalPolynomial(api, ballot.Coefficients[:], xs[j]), plaintexts[j])
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package voteverifier
+
+import (
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bn254"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_emulated"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/std/math/emulated/emparams"
+	"github.com/consensys/gnark/std/recursion/groth16"
+	"github.com/consensys/gnark/std/signature/ecdsa"
+	"github.com/vocdoni/gnark-crypto-primitives/address"
+	"github.com/vocdoni/gnark-crypto-primitives/arbo"
+	"github.com/vocdoni/vocdoni-z-sandbox/circuits"
+	"github.com/vocdoni/vocdoni-z-sandbox/circuits/voteverifier/mimc"
+)
+
+// VerifyVotePolynomialCircuit is the ranked/score-ballot counterpart of
+// VerifyVoteCircuit: it performs the same ballot-proof, signature and census
+// checks, and additionally verifies that the voter's committed ballot
+// polynomial (Ballot) evaluates, at each candidate's x-coordinate in
+// CandidateXs, to the corresponding decoded plaintext in Plaintexts, and
+// that Ballot's commitment matches PolynomialCommitment (the commitment the
+// process fixed when it was created). CandidateXs, Plaintexts and
+// PolynomialCommitment are folded into InputsHash alongside the fields
+// VerifyVoteCircuit already binds, so none of them can be swapped for a
+// different vote after the fact.
+type VerifyVotePolynomialCircuit struct {
+	// Single public input that is the hash of all the public inputs
+	InputsHash frontend.Variable `gnark:",public"`
+	// The following variables are priv-public inputs, so should be hashed
+	// and compared with the InputsHash or CircomPublicInputsHash. They are
+	// hashed in the order circomInputs lists them (ProcessId right after
+	// UserWeight), then CensusRoot and the ranked-ballot fields below.
+	MaxCount         frontend.Variable          // Part of CircomPublicInputsHash & InputsHash
+	ForceUniqueness  frontend.Variable          // Part of CircomPublicInputsHash & InputsHash
+	MaxValue         frontend.Variable          // Part of CircomPublicInputsHash & InputsHash
+	MinValue         frontend.Variable          // Part of CircomPublicInputsHash & InputsHash
+	MaxTotalCost     frontend.Variable          // Part of CircomPublicInputsHash & InputsHash
+	MinTotalCost     frontend.Variable          // Part of CircomPublicInputsHash & InputsHash
+	CostExp          frontend.Variable          // Part of CircomPublicInputsHash & InputsHash
+	CostFromWeight   frontend.Variable          // Part of CircomPublicInputsHash & InputsHash
+	Address          frontend.Variable          // Part of CircomPublicInputsHash & InputsHash
+	UserWeight       frontend.Variable          // Part of CircomPublicInputsHash & InputsHash
+	EncryptionPubKey [2]frontend.Variable       // Part of CircomPublicInputsHash & InputsHash
+	Nullifier        frontend.Variable          // Part of CircomPublicInputsHash & InputsHash
+	Commitment       frontend.Variable          // Part of CircomPublicInputsHash & InputsHash
+	ProcessId        frontend.Variable          // Part of CircomPublicInputsHash & InputsHash
+	EncryptedBallot  [8][2][2]frontend.Variable // Part of CircomPublicInputsHash & InputsHash
+	CensusRoot       frontend.Variable          // Part of InputsHash
+	CensusSiblings   [160]frontend.Variable
+	// PolynomialCommitment is the commitment the process fixed for the
+	// ballot polynomial at creation time; Ballot.Commitment must match it.
+	// Part of InputsHash.
+	PolynomialCommitment frontend.Variable
+	// CandidateXs are the per-candidate x-coordinates the ballot polynomial
+	// is evaluated at, and Plaintexts the expected per-candidate decoded
+	// value at each of them. Part of InputsHash.
+	CandidateXs [8]frontend.Variable
+	Plaintexts  [8]frontend.Variable
+	// Ballot carries the voter's polynomial coefficients and commitment.
+	Ballot circuits.PolynomialBallot
+	// The following variables are private inputs and they are used to verify
+	// the user identity ownership
+	PublicKey ecdsa.PublicKey[emulated.Secp256k1Fp, emulated.Secp256k1Fr]
+	Signature ecdsa.Signature[emulated.Secp256k1Fr]
+	// The following variables are private inputs and they are used to verify
+	// the ballot proof
+	CircomProof            groth16.Proof[sw_bn254.G1Affine, sw_bn254.G2Affine]
+	CircomPublicInputsHash groth16.Witness[sw_bn254.ScalarField]
+	CircomVerificationKey  groth16.VerifyingKey[sw_bn254.G1Affine, sw_bn254.G2Affine, sw_bn254.GTEl] `gnark:"-"`
+}
+
+// circomInputs returns the circom public-private inputs, identical to
+// VerifyVoteCircuit.circomInputs since the ballot-proof side of the circuit
+// is unchanged by the choice of ballot encoding.
+func (c *VerifyVotePolynomialCircuit) circomInputs(api frontend.API) []frontend.Variable {
+	circomPubPrivInputs := []frontend.Variable{
+		c.MaxCount, c.ForceUniqueness, c.MaxValue, c.MinValue, c.MaxTotalCost,
+		c.MinTotalCost, c.CostExp, c.CostFromWeight, c.Address, c.UserWeight,
+		c.ProcessId, c.EncryptionPubKey[0], c.EncryptionPubKey[1], c.Nullifier,
+		c.Commitment,
+	}
+	var flatEncryptedBallot []frontend.Variable
+	for i := 0; i < len(c.EncryptedBallot); i++ {
+		for j := 0; j < len(c.EncryptedBallot[i]); j++ {
+			flatEncryptedBallot = append(flatEncryptedBallot, c.EncryptedBallot[i][j][:]...)
+		}
+	}
+	api.AssertIsEqual(len(flatEncryptedBallot), len(c.EncryptedBallot)*len(c.EncryptedBallot[0])*len(c.EncryptedBallot[0][0]))
+	return append(circomPubPrivInputs, flatEncryptedBallot...)
+}
+
+// checkCircomProof checks the circom proof provided by the user, identically
+// to VerifyVoteCircuit.checkCircomProof.
+func (c *VerifyVotePolynomialCircuit) checkCircomProof(api frontend.API, circomInputs []frontend.Variable) (frontend.Variable, error) {
+	api.AssertIsEqual(len(c.CircomPublicInputsHash.Public), 1)
+	pubCircomInputsHash := c.CircomPublicInputsHash.Public[0]
+	circomHash, err := mimc.NewMiMC(api, ecc.BN254.ScalarField())
+	if err != nil {
+		return nil, err
+	}
+	circomHash.Write(circomInputs...)
+	circomInputsHash := circomHash.Sum()
+	if err := assertEqualToElement(api, circomInputsHash, pubCircomInputsHash); err != nil {
+		return nil, err
+	}
+	verifier, err := groth16.NewVerifier[sw_bn254.ScalarField, sw_bn254.G1Affine, sw_bn254.G2Affine, sw_bn254.GTEl](api)
+	if err != nil {
+		return nil, err
+	}
+	return circomInputsHash, verifier.AssertProof(
+		c.CircomVerificationKey, c.CircomProof, c.CircomPublicInputsHash,
+		groth16.WithCompleteArithmetic())
+}
+
+func (c *VerifyVotePolynomialCircuit) Define(api frontend.API) error {
+	// check circom circuit stuff
+	cInputs := c.circomInputs(api)
+	circomInputsHash, err := c.checkCircomProof(api, cInputs)
+	if err != nil {
+		return err
+	}
+	// check that the input hash matches with the hash of the circom public
+	// inputs plus the address, census root, and the ranked-ballot fields
+	// this circuit adds on top of VerifyVoteCircuit
+	circomHash, err := mimc.NewMiMC(api, ecc.BN254.ScalarField())
+	if err != nil {
+		return err
+	}
+	extra := append([]frontend.Variable{c.CensusRoot, c.PolynomialCommitment}, c.CandidateXs[:]...)
+	extra = append(extra, c.Plaintexts[:]...)
+	circomHash.Write(append(cInputs, extra...)...)
+	inputsHash := circomHash.Sum()
+	api.AssertIsEqual(c.InputsHash, inputsHash)
+	// check the signature of the circom inputs hash
+	msg, err := varToFieldElem[emparams.Secp256k1Fr](api, circomInputsHash)
+	if err != nil {
+		return err
+	}
+	c.PublicKey.Verify(api, sw_emulated.GetCurveParams[emulated.Secp256k1Fp](), msg, &c.Signature)
+	// derive the address from the public key and check it matches the provided
+	// address
+	derivedAddr, censusAddress, err := address.DeriveAddress(api, c.PublicKey)
+	if err != nil {
+		return err
+	}
+	// verify the census proof using the derived address and the user weight
+	// provided as leaf key-value, and the root and siblings provided
+	if err := arbo.CheckInclusionProof(api, censusHashFn, censusAddress,
+		c.UserWeight, c.CensusRoot, c.CensusSiblings[:]); err != nil {
+		return err
+	}
+	api.AssertIsEqual(c.Address, derivedAddr)
+	// verify the ranked/score ballot polynomial evaluates to the decoded
+	// plaintexts at every candidate x-coordinate, and matches the process's
+	// fixed commitment
+	meta := circuits.ProcessMetadata{
+		MaxCount:             c.MaxCount,
+		ForceUniqueness:      c.ForceUniqueness,
+		MaxValue:             c.MaxValue,
+		MinValue:             c.MinValue,
+		MaxTotalCost:         c.MaxTotalCost,
+		MinTotalCost:         c.MinTotalCost,
+		CostExp:              c.CostExp,
+		CostFromWeight:       c.CostFromWeight,
+		PolynomialCommitment: c.PolynomialCommitment,
+	}
+	return checkPolynomialBallot(api, meta, c.Ballot, c.CandidateXs[:], c.Plaintexts[:])
+}
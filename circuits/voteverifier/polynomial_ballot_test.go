@@ -0,0 +1,118 @@
+package voteverifier
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/hash"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/vocdoni/vocdoni-z-sandbox/circuits"
+)
+
+// mimcSumForTest computes the off-circuit MiMC hash of values over
+// BW6-761's scalar field, matching the in-circuit mimc.NewMiMC(api, nil)
+// used throughout this package (censusHashFn, checkPolynomialBallot), so
+// tests don't need to solve a circuit just to obtain a valid hash witness.
+func mimcSumForTest(values ...*big.Int) *big.Int {
+	h := hash.MIMC_BW6_761.New()
+	for _, v := range values {
+		var buf [32]byte
+		v.FillBytes(buf[:])
+		h.Write(buf[:])
+	}
+	return new(big.Int).SetBytes(h.Sum(nil))
+}
+
+// mimcHashForTest is mimcSumForTest for a slice of int64 coefficients.
+func mimcHashForTest(coefficients []int64) *big.Int {
+	values := make([]*big.Int, len(coefficients))
+	for i, c := range coefficients {
+		values[i] = big.NewInt(c)
+	}
+	return mimcSumForTest(values...)
+}
+
+type evalPolynomialCircuit struct {
+	Coefficients [3]frontend.Variable
+	X            frontend.Variable
+	Y            frontend.Variable `gnark:",public"`
+}
+
+func (c *evalPolynomialCircuit) Define(api frontend.API) error {
+	got := evalPolynomial(api, c.Coefficients[:], c.X)
+	api.AssertIsEqual(got, c.Y)
+	return nil
+}
+
+type checkPolynomialBallotCircuit struct {
+	Meta       circuits.ProcessMetadata
+	Ballot     circuits.PolynomialBallot
+	Xs         [3]frontend.Variable
+	Plaintexts [3]frontend.Variable
+}
+
+func (c *checkPolynomialBallotCircuit) Define(api frontend.API) error {
+	return checkPolynomialBallot(api, c.Meta, c.Ballot, c.Xs[:], c.Plaintexts[:])
+}
+
+// TestCheckPolynomialBallot exercises checkPolynomialBallot itself (not just
+// the evalPolynomial helper): it checks that a ballot whose coefficients
+// hash to its own Commitment, whose Commitment matches the process's
+// PolynomialCommitment, and whose polynomial evaluates to the expected
+// plaintext at every candidate x solves; and that tampering with a single
+// plaintext makes solving fail.
+func TestCheckPolynomialBallot(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	coeffs := [8]int64{5, 3, 2, 0, 0, 0, 0, 0} // p(x) = 5 + 3x + 2x^2
+	xs := [3]int64{0, 1, 4}
+	plaintexts := [3]int64{5, 10, 49} // p(0)=5, p(1)=10, p(4)=49
+
+	commitment := mimcHashForTest(coeffs[:])
+
+	var coeffVars [8]frontend.Variable
+	for i, c := range coeffs {
+		coeffVars[i] = c
+	}
+	var xsVars, plaintextVars [3]frontend.Variable
+	for i := range xs {
+		xsVars[i] = xs[i]
+		plaintextVars[i] = plaintexts[i]
+	}
+
+	assignment := &checkPolynomialBallotCircuit{
+		Meta:       circuits.ProcessMetadata{PolynomialCommitment: commitment},
+		Ballot:     circuits.PolynomialBallot{Coefficients: coeffVars, Commitment: commitment},
+		Xs:         xsVars,
+		Plaintexts: plaintextVars,
+	}
+	assert.SolvingSucceeded(&checkPolynomialBallotCircuit{}, assignment, test.WithCurves(ecc.BW6_761))
+
+	tampered := &checkPolynomialBallotCircuit{
+		Meta:       circuits.ProcessMetadata{PolynomialCommitment: commitment},
+		Ballot:     circuits.PolynomialBallot{Coefficients: coeffVars, Commitment: commitment},
+		Xs:         xsVars,
+		Plaintexts: [3]frontend.Variable{5, 10, 50}, // wrong plaintext for x=4
+	}
+	assert.SolvingFailed(&checkPolynomialBallotCircuit{}, tampered, test.WithCurves(ecc.BW6_761))
+}
+
+// TestEvalPolynomial checks Horner's-scheme evaluation against the plain
+// p(x) = c0 + c1*x + c2*x^2 computed in Go for a couple of small values,
+// where no field-modulus wraparound can hide a mistake in the recurrence.
+func TestEvalPolynomial(t *testing.T) {
+	assert := test.NewAssert(t)
+	coeffs := [3]int64{5, 3, 2} // p(x) = 5 + 3x + 2x^2
+
+	for _, x := range []int64{0, 1, 4} {
+		y := coeffs[0] + coeffs[1]*x + coeffs[2]*x*x
+		assignment := &evalPolynomialCircuit{
+			Coefficients: [3]frontend.Variable{coeffs[0], coeffs[1], coeffs[2]},
+			X:            x,
+			Y:            y,
+		}
+		assert.SolvingSucceeded(&evalPolynomialCircuit{}, assignment, test.WithCurves(ecc.BW6_761))
+	}
+}
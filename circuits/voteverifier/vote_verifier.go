@@ -1,9 +1,10 @@
 // voteverifier package contains the Gnark circuit definition that verifies a
 // vote package to be aggregated by the vote aggregator and included in a new
 // state transition. A vote package includes a ballot proof (generated from
-// a circom circuit with snarkjs), the public inputs of the ballot proof
-// circuit, the signature of the public inputs, and a census proof. The vote
-// package is valid if the ballot proof is valid if:
+// a circom circuit with snarkjs, or a gnark-style PLONK circuit via
+// VerifyVotePlonkCircuit), the public inputs of the ballot proof circuit, the
+// signature of the public inputs, and a census proof. The vote package is
+// valid if the ballot proof is valid if:
 //   - The public inputs of the ballot proof are valid (match with the hash
 //     provided).
 //   - The ballot proof is valid for the public inputs.
@@ -55,6 +56,7 @@ import (
 	"github.com/consensys/gnark/std/math/emulated"
 	"github.com/consensys/gnark/std/math/emulated/emparams"
 	"github.com/consensys/gnark/std/recursion/groth16"
+	"github.com/consensys/gnark/std/recursion/plonk"
 	"github.com/consensys/gnark/std/signature/ecdsa"
 	"github.com/vocdoni/gnark-crypto-primitives/address"
 	"github.com/vocdoni/gnark-crypto-primitives/arbo"
@@ -233,3 +235,142 @@ func (c *VerifyVoteCircuit) Define(api frontend.API) error {
 	api.AssertIsEqual(c.Address, derivedAddr)
 	return nil
 }
+
+// VerifyVotePlonkCircuit is the PLONK-backed counterpart of VerifyVoteCircuit.
+// It verifies the same vote package (ballot proof, signature and census
+// inclusion) but accepts a ballot proof generated by a gnark-style PLONK
+// circuit instead of a Circom/Groth16 one, so voters can choose either
+// backend without forking the aggregator.
+type VerifyVotePlonkCircuit struct {
+	// Single public input that is the hash of all the public inputs
+	InputsHash frontend.Variable `gnark:",public"`
+	// The following variables are priv-public inputs, so should be hashed
+	// and compared with the InputsHash or CircomPublicInputsHash. They are
+	// hashed in the order circomInputs lists them (ProcessId right after
+	// UserWeight), then CensusRoot.
+	MaxCount         frontend.Variable          // Part of CircomPublicInputsHash & InputsHash
+	ForceUniqueness  frontend.Variable          // Part of CircomPublicInputsHash & InputsHash
+	MaxValue         frontend.Variable          // Part of CircomPublicInputsHash & InputsHash
+	MinValue         frontend.Variable          // Part of CircomPublicInputsHash & InputsHash
+	MaxTotalCost     frontend.Variable          // Part of CircomPublicInputsHash & InputsHash
+	MinTotalCost     frontend.Variable          // Part of CircomPublicInputsHash & InputsHash
+	CostExp          frontend.Variable          // Part of CircomPublicInputsHash & InputsHash
+	CostFromWeight   frontend.Variable          // Part of CircomPublicInputsHash & InputsHash
+	Address          frontend.Variable          // Part of CircomPublicInputsHash & InputsHash
+	UserWeight       frontend.Variable          // Part of CircomPublicInputsHash & InputsHash
+	EncryptionPubKey [2]frontend.Variable       // Part of CircomPublicInputsHash & InputsHash
+	Nullifier        frontend.Variable          // Part of CircomPublicInputsHash & InputsHash
+	Commitment       frontend.Variable          // Part of CircomPublicInputsHash & InputsHash
+	ProcessId        frontend.Variable          // Part of CircomPublicInputsHash & InputsHash
+	EncryptedBallot  [8][2][2]frontend.Variable // Part of CircomPublicInputsHash & InputsHash
+	CensusRoot       frontend.Variable          // Part of InputsHash
+	CensusSiblings   [160]frontend.Variable
+	// The following variables are private inputs and they are used to verify
+	// the user identity ownership
+	PublicKey ecdsa.PublicKey[emulated.Secp256k1Fp, emulated.Secp256k1Fr]
+	Signature ecdsa.Signature[emulated.Secp256k1Fr]
+	// The following variables are private inputs and they are used to verify
+	// the PLONK ballot proof
+	CircomProof            plonk.Proof[sw_bn254.ScalarField, sw_bn254.G1Affine, sw_bn254.G2Affine]
+	CircomPublicInputsHash plonk.Witness[sw_bn254.ScalarField]
+	CircomVerificationKey  plonk.VerifyingKey[sw_bn254.ScalarField, sw_bn254.G1Affine, sw_bn254.G2Affine] `gnark:"-"`
+}
+
+// circomInputs returns the circom public-private inputs that are used to hash
+// them and compare them with the unique public input of the PLONK circuit. It
+// asserts that the length of the flat encrypted ballot is correct and returns
+// the circom public-private inputs. It mirrors VerifyVoteCircuit.circomInputs.
+func (c *VerifyVotePlonkCircuit) circomInputs(api frontend.API) []frontend.Variable {
+	circomPubPrivInputs := []frontend.Variable{
+		c.MaxCount, c.ForceUniqueness, c.MaxValue, c.MinValue, c.MaxTotalCost,
+		c.MinTotalCost, c.CostExp, c.CostFromWeight, c.Address, c.UserWeight,
+		c.ProcessId, c.EncryptionPubKey[0], c.EncryptionPubKey[1], c.Nullifier,
+		c.Commitment,
+	}
+	var flatEncryptedBallot []frontend.Variable
+	for i := 0; i < len(c.EncryptedBallot); i++ {
+		for j := 0; j < len(c.EncryptedBallot[i]); j++ {
+			flatEncryptedBallot = append(flatEncryptedBallot, c.EncryptedBallot[i][j][:]...)
+		}
+	}
+	api.AssertIsEqual(len(flatEncryptedBallot), len(c.EncryptedBallot)*len(c.EncryptedBallot[0])*len(c.EncryptedBallot[0][0]))
+	return append(circomPubPrivInputs, flatEncryptedBallot...)
+}
+
+// checkCircomPlonkProof checks the PLONK ballot proof provided by the user. It
+// hashes the circom public-private inputs and compares them with the unique
+// public input of the PLONK circuit, then runs the in-circuit PLONK verifier
+// (KZG batched opening over the Fiat-Shamir-derived evaluation point) against
+// the verifying key supplied as a compile-time constant, the same way
+// CircomVerificationKey is provided for the Groth16 path. It returns the hash
+// of the circom public-private inputs if the verification succeeds and an
+// error if it fails.
+func (c *VerifyVotePlonkCircuit) checkCircomPlonkProof(api frontend.API, circomInputs []frontend.Variable) (frontend.Variable, error) {
+	// check that the circom witness only contains a single public input
+	// (the hash of all the public-private inputs)
+	api.AssertIsEqual(len(c.CircomPublicInputsHash.Public), 1)
+	pubCircomInputsHash := c.CircomPublicInputsHash.Public[0]
+	// create the hash of the circom public-private inputs over the scalar field
+	// of the bn254 curve (used by circom)
+	circomHash, err := mimc.NewMiMC(api, ecc.BN254.ScalarField())
+	if err != nil {
+		return nil, err
+	}
+	// hash the circom public-private inputs and compare them with the unique
+	// public input of the circom circuit
+	circomHash.Write(circomInputs...)
+	circomInputsHash := circomHash.Sum()
+	if err := assertEqualToElement(api, circomInputsHash, pubCircomInputsHash); err != nil {
+		return nil, err
+	}
+	// verify the PLONK ballot proof over the bn254 curve (used by circom): the
+	// verifier folds the BSB22 commitments into the public inputs vector,
+	// evaluates Z_H and the Lagrange basis at the Fiat-Shamir challenge point,
+	// and checks the batched KZG opening.
+	verifier, err := plonk.NewVerifier[sw_bn254.ScalarField, sw_bn254.G1Affine, sw_bn254.G2Affine, sw_bn254.GTEl](api)
+	if err != nil {
+		return nil, err
+	}
+	return circomInputsHash, verifier.AssertProof(
+		c.CircomVerificationKey, c.CircomProof, c.CircomPublicInputsHash, plonk.WithCompleteArithmetic())
+}
+
+func (c *VerifyVotePlonkCircuit) Define(api frontend.API) error {
+	// check circom circuit stuff
+	cInputs := c.circomInputs(api)
+	circomInputsHash, err := c.checkCircomPlonkProof(api, cInputs)
+	if err != nil {
+		return err
+	}
+	// check that the input hash matches with the hash of the circom public
+	// inputs with the address and the census root, here the hash function is
+	// over the scalar field of the bn254 curve
+	circomHash, err := mimc.NewMiMC(api, ecc.BN254.ScalarField())
+	if err != nil {
+		api.Println(err)
+		return err
+	}
+	circomHash.Write(append(cInputs, c.CensusRoot)...)
+	inputsHash := circomHash.Sum()
+	api.AssertIsEqual(c.InputsHash, inputsHash)
+	// check the signature of the circom inputs hash
+	msg, err := varToFieldElem[emparams.Secp256k1Fr](api, circomInputsHash)
+	if err != nil {
+		return err
+	}
+	c.PublicKey.Verify(api, sw_emulated.GetCurveParams[emulated.Secp256k1Fp](), msg, &c.Signature)
+	// derive the address from the public key and check it matches the provided
+	// address
+	derivedAddr, censusAddress, err := address.DeriveAddress(api, c.PublicKey)
+	if err != nil {
+		return err
+	}
+	// verify the census proof using the derived address and the user weight
+	// provided as leaf key-value, adn the root and siblings provided
+	if err := arbo.CheckInclusionProof(api, censusHashFn, censusAddress,
+		c.UserWeight, c.CensusRoot, c.CensusSiblings[:]); err != nil {
+		return err
+	}
+	api.AssertIsEqual(c.Address, derivedAddr)
+	return nil
+}
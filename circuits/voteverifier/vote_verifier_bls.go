@@ -0,0 +1,245 @@
+package voteverifier
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/constraint/solver"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bn254"
+	"github.com/consensys/gnark/std/algebra/native/sw_bls12377"
+	"github.com/consensys/gnark/std/recursion/groth16"
+	"github.com/vocdoni/gnark-crypto-primitives/arbo"
+	"github.com/vocdoni/vocdoni-z-sandbox/circuits/voteverifier/mimc"
+)
+
+func init() {
+	solver.RegisterHint(hashToG1Hint)
+}
+
+// VerifyVoteBLSCircuit is the BLS12-377 counterpart of VerifyVoteCircuit for
+// voters that already hold a BLS keypair (e.g. validators or rollup
+// operators) instead of a secp256k1 one. It verifies the same ballot proof
+// and census inclusion, but replaces the ECDSA signature check with a BLS
+// pairing check e(H(m), pk) == e(sigma, g2), and derives the census address
+// by hashing the G2 public key's affine coordinates with MiMC instead of
+// deriving a keccak-based Ethereum address. The key is not compressed
+// before hashing.
+type VerifyVoteBLSCircuit struct {
+	// Single public input that is the hash of all the public inputs
+	InputsHash frontend.Variable `gnark:",public"`
+	// The following variables are priv-public inputs, so should be hashed
+	// and compared with the InputsHash or CircomPublicInputsHash. They are
+	// hashed in the order circomInputs lists them (ProcessId right after
+	// UserWeight), then CensusRoot.
+	MaxCount         frontend.Variable          // Part of CircomPublicInputsHash & InputsHash
+	ForceUniqueness  frontend.Variable          // Part of CircomPublicInputsHash & InputsHash
+	MaxValue         frontend.Variable          // Part of CircomPublicInputsHash & InputsHash
+	MinValue         frontend.Variable          // Part of CircomPublicInputsHash & InputsHash
+	MaxTotalCost     frontend.Variable          // Part of CircomPublicInputsHash & InputsHash
+	MinTotalCost     frontend.Variable          // Part of CircomPublicInputsHash & InputsHash
+	CostExp          frontend.Variable          // Part of CircomPublicInputsHash & InputsHash
+	CostFromWeight   frontend.Variable          // Part of CircomPublicInputsHash & InputsHash
+	Address          frontend.Variable          // Part of CircomPublicInputsHash & InputsHash
+	UserWeight       frontend.Variable          // Part of CircomPublicInputsHash & InputsHash
+	EncryptionPubKey [2]frontend.Variable       // Part of CircomPublicInputsHash & InputsHash
+	Nullifier        frontend.Variable          // Part of CircomPublicInputsHash & InputsHash
+	Commitment       frontend.Variable          // Part of CircomPublicInputsHash & InputsHash
+	ProcessId        frontend.Variable          // Part of CircomPublicInputsHash & InputsHash
+	EncryptedBallot  [8][2][2]frontend.Variable // Part of CircomPublicInputsHash & InputsHash
+	CensusRoot       frontend.Variable          // Part of InputsHash
+	CensusSiblings   [160]frontend.Variable
+	// The following variables are private inputs and they are used to verify
+	// the user identity ownership over BLS12-377
+	PublicKey sw_bls12377.G2Affine
+	Signature sw_bls12377.G1Affine
+	// The following variables are private inputs and they are used to verify
+	// the ballot proof
+	CircomProof            groth16.Proof[sw_bn254.G1Affine, sw_bn254.G2Affine]
+	CircomPublicInputsHash groth16.Witness[sw_bn254.ScalarField]
+	CircomVerificationKey  groth16.VerifyingKey[sw_bn254.G1Affine, sw_bn254.G2Affine, sw_bn254.GTEl] `gnark:"-"`
+}
+
+// circomInputs returns the circom public-private inputs that are used to hash
+// them and compare them with the unique public input of the circom circuit.
+// It is identical to VerifyVoteCircuit.circomInputs since the ballot proof
+// side of the circuit is unchanged by the choice of identity scheme.
+func (c *VerifyVoteBLSCircuit) circomInputs(api frontend.API) []frontend.Variable {
+	circomPubPrivInputs := []frontend.Variable{
+		c.MaxCount, c.ForceUniqueness, c.MaxValue, c.MinValue, c.MaxTotalCost,
+		c.MinTotalCost, c.CostExp, c.CostFromWeight, c.Address, c.UserWeight,
+		c.ProcessId, c.EncryptionPubKey[0], c.EncryptionPubKey[1], c.Nullifier,
+		c.Commitment,
+	}
+	var flatEncryptedBallot []frontend.Variable
+	for i := 0; i < len(c.EncryptedBallot); i++ {
+		for j := 0; j < len(c.EncryptedBallot[i]); j++ {
+			flatEncryptedBallot = append(flatEncryptedBallot, c.EncryptedBallot[i][j][:]...)
+		}
+	}
+	api.AssertIsEqual(len(flatEncryptedBallot), len(c.EncryptedBallot)*len(c.EncryptedBallot[0])*len(c.EncryptedBallot[0][0]))
+	return append(circomPubPrivInputs, flatEncryptedBallot...)
+}
+
+// checkCircomProof checks the circom proof provided by the user, identically
+// to VerifyVoteCircuit.checkCircomProof.
+func (c *VerifyVoteBLSCircuit) checkCircomProof(api frontend.API, circomInputs []frontend.Variable) (frontend.Variable, error) {
+	api.AssertIsEqual(len(c.CircomPublicInputsHash.Public), 1)
+	pubCircomInputsHash := c.CircomPublicInputsHash.Public[0]
+	circomHash, err := mimc.NewMiMC(api, ecc.BN254.ScalarField())
+	if err != nil {
+		return nil, err
+	}
+	circomHash.Write(circomInputs...)
+	circomInputsHash := circomHash.Sum()
+	if err := assertEqualToElement(api, circomInputsHash, pubCircomInputsHash); err != nil {
+		return nil, err
+	}
+	verifier, err := groth16.NewVerifier[sw_bn254.ScalarField, sw_bn254.G1Affine, sw_bn254.G2Affine, sw_bn254.GTEl](api)
+	if err != nil {
+		return nil, err
+	}
+	return circomInputsHash, verifier.AssertProof(
+		c.CircomVerificationKey, c.CircomProof, c.CircomPublicInputsHash,
+		groth16.WithCompleteArithmetic())
+}
+
+// bls12377G1B is the constant term b in BLS12-377 G1's short Weierstrass
+// equation y^2 = x^3 + b over its base field.
+const bls12377G1B = 1
+
+// maxHashToG1Attempts bounds how far hashToG1Hint's try-and-increment search
+// is allowed to walk from msg. Without this bound, ctr is otherwise
+// unconstrained in-circuit, so a prover could pick any ctr landing on some
+// unrelated valid curve point and decouple msgPoint from msg entirely,
+// rather than only nudging x by the handful of increments the off-circuit
+// search actually needs in practice.
+const maxHashToG1Attempts = 256
+
+// hashToG1 deterministically maps msg to a point on BLS12-377's G1 using
+// try-and-increment: hashToG1Hint searches off-circuit for the smallest
+// counter ctr such that x = msg+ctr satisfies the curve equation, and
+// returns ctr together with a square root y of x^3+b. The circuit does not
+// trust the hint's output as-is: it independently recomputes x = msg+ctr
+// with native field arithmetic and asserts y^2 == x^3+b, and additionally
+// bounds ctr itself to maxHashToG1Attempts so a prover cannot pick some other
+// far-away ctr landing on an unrelated valid curve point and substitute a
+// point unrelated to msg.
+//
+// This replaces an earlier H(m) = h(m)*G construction, which mapped every
+// message to a known scalar multiple of the fixed generator. That made the
+// discrete log of H(m) relative to G publicly computable, so anyone holding
+// a single valid signature sigma0 = sk*H(m0) could forge a signature for any
+// other message m via sigma = (h(m)/h(m0)) * sigma0, without ever learning
+// sk. Mapping to a point via its coordinates instead means recovering its
+// discrete log relative to G requires solving ECDLP, closing that forgery.
+func hashToG1(api frontend.API, msg frontend.Variable) (sw_bls12377.G1Affine, error) {
+	out, err := api.NewHint(hashToG1Hint, 2, msg)
+	if err != nil {
+		return sw_bls12377.G1Affine{}, err
+	}
+	ctr, y := out[0], out[1]
+	api.AssertIsLessOrEqual(ctr, maxHashToG1Attempts)
+
+	x := api.Add(msg, ctr)
+	xSq := api.Mul(x, x)
+	xCubed := api.Mul(xSq, x)
+	rhs := api.Add(xCubed, bls12377G1B)
+	ySq := api.Mul(y, y)
+	api.AssertIsEqual(ySq, rhs)
+
+	return sw_bls12377.G1Affine{X: x, Y: y}, nil
+}
+
+// hashToG1Hint is the off-circuit search used by hashToG1: starting from
+// x = msg mod p, it increments x by one until x^3+b is a quadratic residue
+// modulo the base field p, and returns the number of increments together
+// with the resulting square root.
+func hashToG1Hint(mod *big.Int, inputs, outputs []*big.Int) error {
+	x := new(big.Int).Mod(inputs[0], mod)
+	one := big.NewInt(1)
+	b := big.NewInt(bls12377G1B)
+	ctr := new(big.Int)
+	rhs := new(big.Int)
+	y := new(big.Int)
+	for {
+		rhs.Exp(x, big.NewInt(3), mod)
+		rhs.Add(rhs, b)
+		rhs.Mod(rhs, mod)
+		if y.ModSqrt(rhs, mod) != nil {
+			break
+		}
+		x.Add(x, one)
+		x.Mod(x, mod)
+		ctr.Add(ctr, one)
+	}
+	outputs[0] = ctr
+	outputs[1] = y
+	return nil
+}
+
+// deriveBLSCensusAddress derives the census address of a BLS voter by
+// hashing the four base-field limbs of the uncompressed G2 public key
+// (X.A0, X.A1, Y.A0, Y.A1) with MiMC, in place of the keccak-derived
+// Ethereum address used for ECDSA voters.
+func deriveBLSCensusAddress(api frontend.API, pubKey sw_bls12377.G2Affine) (frontend.Variable, error) {
+	h, err := mimc.NewMiMC(api, nil)
+	if err != nil {
+		return nil, err
+	}
+	h.Write(pubKey.P.X.A0, pubKey.P.X.A1, pubKey.P.Y.A0, pubKey.P.Y.A1)
+	return h.Sum(), nil
+}
+
+func (c *VerifyVoteBLSCircuit) Define(api frontend.API) error {
+	// check circom circuit stuff
+	cInputs := c.circomInputs(api)
+	circomInputsHash, err := c.checkCircomProof(api, cInputs)
+	if err != nil {
+		return err
+	}
+	// check that the input hash matches with the hash of the circom public
+	// inputs with the address and the census root
+	circomHash, err := mimc.NewMiMC(api, ecc.BN254.ScalarField())
+	if err != nil {
+		return err
+	}
+	circomHash.Write(append(cInputs, c.CensusRoot)...)
+	inputsHash := circomHash.Sum()
+	api.AssertIsEqual(c.InputsHash, inputsHash)
+	// verify the BLS signature over the circom public inputs hash: the
+	// message is hashed to a G1 point and the pairing equation
+	// e(H(m), pk) == e(sigma, g2) is checked with the in-circuit BLS12-377
+	// pairing, g2 being the fixed G2 generator
+	msgPoint, err := hashToG1(api, circomInputsHash)
+	if err != nil {
+		return err
+	}
+	pairing, err := sw_bls12377.NewPairing(api)
+	if err != nil {
+		return err
+	}
+	g2Gen := sw_bls12377.G2Affine{}
+	g2Gen.AssignGenerator(api)
+	lhs, err := pairing.Pair([]*sw_bls12377.G1Affine{&msgPoint}, []*sw_bls12377.G2Affine{&c.PublicKey})
+	if err != nil {
+		return err
+	}
+	rhs, err := pairing.Pair([]*sw_bls12377.G1Affine{&c.Signature}, []*sw_bls12377.G2Affine{&g2Gen})
+	if err != nil {
+		return err
+	}
+	pairing.AssertIsEqual(lhs, rhs)
+	// derive the census address from the BLS public key and check it matches
+	// the census inclusion proof and the provided address
+	censusAddress, err := deriveBLSCensusAddress(api, c.PublicKey)
+	if err != nil {
+		return err
+	}
+	if err := arbo.CheckInclusionProof(api, censusHashFn, censusAddress,
+		c.UserWeight, c.CensusRoot, c.CensusSiblings[:]); err != nil {
+		return err
+	}
+	api.AssertIsEqual(c.Address, censusAddress)
+	return nil
+}
@@ -0,0 +1,54 @@
+package voteverifier
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+)
+
+// TestHashToG1Hint checks the off-circuit search that backs hashToG1: for a
+// range of messages, the returned (ctr, y) must satisfy the BLS12-377 G1
+// curve equation for x = msg+ctr, and repeated calls for the same message
+// must be deterministic. This is the property that replaced the forgeable
+// H(m) = h(m)*G construction: the hint no longer exposes a known discrete
+// log relation between the hash of different messages.
+func TestHashToG1Hint(t *testing.T) {
+	mod := ecc.BW6_761.ScalarField() // BLS12-377's base field
+	b := big.NewInt(bls12377G1B)
+
+	for _, msg := range []int64{0, 1, 2, 12345, 999999} {
+		inputs := []*big.Int{big.NewInt(msg)}
+		outputs := make([]*big.Int, 2)
+		if err := hashToG1Hint(mod, inputs, outputs); err != nil {
+			t.Fatalf("hashToG1Hint(%d) returned an error: %v", msg, err)
+		}
+		ctr, y := outputs[0], outputs[1]
+
+		x := new(big.Int).Add(big.NewInt(msg), ctr)
+		x.Mod(x, mod)
+
+		rhs := new(big.Int).Exp(x, big.NewInt(3), mod)
+		rhs.Add(rhs, b)
+		rhs.Mod(rhs, mod)
+
+		ySq := new(big.Int).Mul(y, y)
+		ySq.Mod(ySq, mod)
+
+		if ySq.Cmp(rhs) != 0 {
+			t.Fatalf("hashToG1Hint(%d): y^2 != x^3+b: got y^2=%s, x^3+b=%s", msg, ySq, rhs)
+		}
+
+		outputs2 := make([]*big.Int, 2)
+		if err := hashToG1Hint(mod, inputs, outputs2); err != nil {
+			t.Fatalf("hashToG1Hint(%d) second call returned an error: %v", msg, err)
+		}
+		if outputs2[0].Cmp(ctr) != 0 || outputs2[1].Cmp(y) != 0 {
+			t.Fatalf("hashToG1Hint(%d) is not deterministic across calls", msg)
+		}
+
+		if ctr.Cmp(big.NewInt(maxHashToG1Attempts)) > 0 {
+			t.Fatalf("hashToG1Hint(%d): ctr=%s exceeds maxHashToG1Attempts=%d, hashToG1 would reject this point", msg, ctr, maxHashToG1Attempts)
+		}
+	}
+}
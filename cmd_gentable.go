@@ -0,0 +1,16 @@
+package main
+
+import "fmt"
+
+// GenerateTableCommand pre-generates a BSGSTable for the given max-message
+// bound and window, and persists it to path. It is meant to be wired up as
+// a CLI subcommand (e.g. `gentable <path> <maxMessage> <window>`) so the
+// expensive baby-step precomputation is paid once per bound instead of once
+// per decryption.
+func GenerateTableCommand(path string, maxMessage, window uint64) error {
+	table := NewBSGSTable(maxMessage, window)
+	if err := table.Save(path); err != nil {
+		return fmt.Errorf("failed to save BSGS table to %s: %w", path, err)
+	}
+	return nil
+}
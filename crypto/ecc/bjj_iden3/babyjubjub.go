@@ -32,20 +32,26 @@ func (g *BJJ) Order() *big.Int {
 }
 
 func (g *BJJ) Add(a, b curve.Point) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
 	g.inner = g.inner.Projective().Add(a.(*BJJ).inner.Projective(), b.(*BJJ).inner.Projective()).Affine()
 }
 
+// SafeAdd is kept for backwards compatibility with existing callers; Add
+// itself now takes the lock, so this is just an alias for it.
 func (g *BJJ) SafeAdd(a, b curve.Point) {
-	g.lock.Lock()
-	defer g.lock.Unlock()
 	g.Add(a, b)
 }
 
 func (g *BJJ) ScalarMult(a curve.Point, scalar *big.Int) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
 	g.inner = g.inner.Mul(scalar, a.(*BJJ).inner)
 }
 
 func (g *BJJ) ScalarBaseMult(scalar *big.Int) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
 	g.inner = g.inner.Mul(scalar, babyjubjub.B8)
 }
 
@@ -87,6 +93,8 @@ func (g *BJJ) Equal(a curve.Point) bool {
 }
 
 func (g *BJJ) Neg(a curve.Point) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
 	proj := g.inner.Projective()
 	proj.X = proj.X.Neg(proj.X)
 	g.inner.X = g.inner.X.Set(proj.Affine().X)
@@ -95,6 +103,8 @@ func (g *BJJ) Neg(a curve.Point) {
 }
 
 func (g *BJJ) SetZero() {
+	g.lock.Lock()
+	defer g.lock.Unlock()
 	p := g.inner.Projective()
 	p.X.SetZero() // Set X to 0
 	p.Y.SetOne()  // Set Y to 1
@@ -103,11 +113,15 @@ func (g *BJJ) SetZero() {
 }
 
 func (g *BJJ) Set(a curve.Point) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
 	g.inner.X = g.inner.X.Set(a.(*BJJ).inner.X)
 	g.inner.Y = g.inner.Y.Set(a.(*BJJ).inner.Y)
 }
 
 func (g *BJJ) SetGenerator() {
+	g.lock.Lock()
+	defer g.lock.Unlock()
 	gen := babyjubjub.B8
 	g.inner.X = g.inner.X.Set(gen.X)
 	g.inner.Y = g.inner.Y.Set(gen.Y)
@@ -123,6 +137,75 @@ func (g *BJJ) Point() (*big.Int, *big.Int) {
 	return g.inner.X, g.inner.Y
 }
 
+// pippengerWindowBits is the window size (in bits) used by MultiAdd's
+// bucketing: MultiAdd always uses 2^4 = 16 buckets. The window does not
+// grow with the number of points n (it is not derived from the
+// c ~ log2(n) - 3 rule of thumb).
+const pippengerWindowBits = 4
+
+// BatchScalarBaseMult computes scalars[i]*G for every scalar in one pass,
+// amortizing the doublings of the shared generator G across the whole batch
+// instead of recomputing them independently for each ScalarBaseMult call.
+func BatchScalarBaseMult(scalars []*big.Int) []curve.Point {
+	results := make([]curve.Point, len(scalars))
+	// precompute the doublings of G once: doublings[i] = 2^i * G
+	maxBits := 0
+	for _, s := range scalars {
+		if bl := s.BitLen(); bl > maxBits {
+			maxBits = bl
+		}
+	}
+	doublings := make([]*babyjubjub.Point, maxBits)
+	cur := babyjubjub.B8.Projective()
+	for i := 0; i < maxBits; i++ {
+		doublings[i] = cur.Affine()
+		cur = cur.Add(cur, cur)
+	}
+	for i, s := range scalars {
+		acc := babyjubjub.NewPoint().Projective()
+		acc.X.SetZero()
+		acc.Y.SetOne()
+		acc.Z.SetOne()
+		for bit := 0; bit < s.BitLen(); bit++ {
+			if s.Bit(bit) == 1 {
+				acc = acc.Add(acc, doublings[bit].Projective())
+			}
+		}
+		results[i] = &BJJ{inner: acc.Affine()}
+	}
+	return results
+}
+
+// MultiAdd sums points using a windowed Pippenger-style bucketing scheme on
+// the BabyJubJub projective form: points are grouped into 2^c buckets keyed
+// by a window of their index, each bucket is summed projectively, and the
+// bucket sums are combined at the end. This avoids the per-add
+// projective<->affine conversion that plain repeated Add calls pay, since
+// intermediate sums stay in projective form until the final result.
+func MultiAdd(points []curve.Point) curve.Point {
+	numBuckets := 1 << pippengerWindowBits
+	buckets := make([]*babyjubjub.PointProjective, numBuckets)
+	for i := range buckets {
+		p := babyjubjub.NewPoint().Projective()
+		p.X.SetZero()
+		p.Y.SetOne()
+		p.Z.SetOne()
+		buckets[i] = p
+	}
+	for i, pt := range points {
+		b := i % numBuckets
+		buckets[b] = buckets[b].Add(buckets[b], pt.(*BJJ).inner.Projective())
+	}
+	total := babyjubjub.NewPoint().Projective()
+	total.X.SetZero()
+	total.Y.SetOne()
+	total.Z.SetOne()
+	for _, b := range buckets {
+		total = total.Add(total, b)
+	}
+	return &BJJ{inner: total.Affine()}
+}
+
 func (g *BJJ) SetPoint(x, y *big.Int) curve.Point {
 	g = &BJJ{inner: babyjubjub.NewPoint()}
 	g.inner.X = g.inner.X.Set(x)
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+)
+
+// ReRandomize returns a fresh encryption of the same plaintext as ct under
+// the combined public key pk: (C1+r*G, C2+r*pk). Because the scheme is
+// additively homomorphic, this is indistinguishable from a brand-new
+// encryption to anyone without r, which is what lets a mixnet stage break
+// the link between a voter and their ballot before decryption.
+func ReRandomize(ct *Ciphertext, pk *G1, r *big.Int) *Ciphertext {
+	rG := &G1{}
+	rG.ScalarBaseMult(r)
+	rPk := &G1{}
+	rPk.ScalarMult(pk, r)
+
+	c1 := &G1{}
+	c1.Add(ct.C1, rG)
+	c2 := &G1{}
+	c2.Add(ct.C2, rPk)
+
+	return &Ciphertext{C1: c1, C2: c2}
+}
+
+// ShuffleProof proves that out is a re-encryption of in under the
+// permutation Perm: out[Perm[i]] is a re-randomization of in[i]. Perm is
+// revealed in the clear rather than hidden: an earlier version of this
+// proof tried to hide it by having the prover supply a pre-weighted input
+// aggregate (WeightedInputC1/C2) for the verifier to trust, but nothing
+// bound that aggregate to the actual `in` ciphertexts, so a prover could
+// fabricate an aggregate matching any `out` regardless of `in`. Revealing
+// Perm lets the verifier derive the weighted input aggregate itself from
+// the real `in` ciphertexts, at the cost of no longer hiding which input
+// produced which output; batching the per-element re-randomization checks
+// into one random linear combination is still what makes this proof
+// cheaper than n independent checks.
+type ShuffleProof struct {
+	// Challenge is the Fiat-Shamir challenge c derived from the input and
+	// output ciphertext transcript.
+	Challenge *big.Int
+	// WeightedRandomness is Sum_i(r_i * c^Perm[i]) mod Order, where r_i is
+	// the re-randomization factor applied to in[i].
+	WeightedRandomness *big.Int
+	// Perm maps each input index i to the output position in[i] was moved
+	// to: out[Perm[i]] is the re-randomization of in[i].
+	Perm []int
+}
+
+// shuffleChallenge derives the Fiat-Shamir challenge c binding the
+// transcript of the input and shuffled output ciphertexts.
+func shuffleChallenge(in, out []*Ciphertext) *big.Int {
+	h := sha256.New()
+	for _, ct := range in {
+		h.Write([]byte(ct.C1.String()))
+		h.Write([]byte(ct.C2.String()))
+	}
+	for _, ct := range out {
+		h.Write([]byte(ct.C1.String()))
+		h.Write([]byte(ct.C2.String()))
+	}
+	c := new(big.Int).SetBytes(h.Sum(nil))
+	return c.Mod(c, Order)
+}
+
+// Shuffle applies a random permutation pi and fresh re-randomization
+// factors to cts under pk, returning the shuffled ciphertexts and a
+// ShuffleProof that out is a re-encryption of cts under pi. The proof
+// carries pi in the clear (ShuffleProof.Perm).
+func Shuffle(cts []*Ciphertext, pk *G1) ([]*Ciphertext, *ShuffleProof, error) {
+	n := len(cts)
+	perm := make([]int, n)
+	for i := range perm {
+		perm[i] = i
+	}
+	for i := n - 1; i > 0; i-- {
+		j, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return nil, nil, err
+		}
+		jInt := int(j.Int64())
+		perm[i], perm[jInt] = perm[jInt], perm[i]
+	}
+
+	// rs[i] is the re-randomization factor applied to cts[i], which ends up
+	// at output position perm[i].
+	rs := make([]*big.Int, n)
+	out := make([]*Ciphertext, n)
+	for i, p := range perm {
+		r, err := rand.Int(rand.Reader, Order)
+		if err != nil {
+			return nil, nil, err
+		}
+		rs[i] = r
+		out[p] = ReRandomize(cts[i], pk, r)
+	}
+
+	c := shuffleChallenge(cts, out)
+
+	// weightedRandomness = Sum_i(r_i * c^perm[i]), matching the exponent
+	// the verifier will assign to out[perm[i]].
+	weightedRandomness := big.NewInt(0)
+	for i, p := range perm {
+		cPowP := new(big.Int).Exp(c, big.NewInt(int64(p)), Order)
+		term := new(big.Int).Mul(rs[i], cPowP)
+		weightedRandomness.Add(weightedRandomness, term)
+	}
+	weightedRandomness.Mod(weightedRandomness, Order)
+
+	proof := &ShuffleProof{
+		Challenge:          c,
+		WeightedRandomness: weightedRandomness,
+		Perm:               perm,
+	}
+	return out, proof, nil
+}
+
+// VerifyShuffle checks a ShuffleProof produced by Shuffle. It independently
+// derives the weighted input aggregate Sum_i(c^Perm[i] * in[i]) from the
+// actual `in` ciphertexts and the revealed Perm — never trusting a
+// prover-supplied aggregate — and asserts it equals the weighted output
+// aggregate Sum_p(c^p * out[p]) re-randomized by WeightedRandomness.
+func VerifyShuffle(in, out []*Ciphertext, pk *G1, proof *ShuffleProof) (bool, error) {
+	n := len(in)
+	if n != len(out) {
+		return false, fmt.Errorf("input and output ciphertext counts differ: %d vs %d", len(in), len(out))
+	}
+	if len(proof.Perm) != n {
+		return false, fmt.Errorf("shuffle proof permutation length %d does not match ciphertext count %d", len(proof.Perm), n)
+	}
+	seen := make([]bool, n)
+	for _, p := range proof.Perm {
+		if p < 0 || p >= n || seen[p] {
+			return false, fmt.Errorf("shuffle proof permutation is not a valid bijection of [0, %d)", n)
+		}
+		seen[p] = true
+	}
+
+	expectedChallenge := shuffleChallenge(in, out)
+	if expectedChallenge.Cmp(proof.Challenge) != 0 {
+		return false, fmt.Errorf("shuffle proof challenge does not match the input/output transcript")
+	}
+
+	// weightedInC1/C2 = Sum_i(c^Perm[i] * in[i].C1/C2), computed from the
+	// real `in` ciphertexts and the revealed permutation.
+	weightedInC1 := &G1{}
+	weightedInC1.SetZero()
+	weightedInC2 := &G1{}
+	weightedInC2.SetZero()
+	for i, p := range proof.Perm {
+		cPowP := new(big.Int).Exp(proof.Challenge, big.NewInt(int64(p)), Order)
+		term1 := &G1{}
+		term1.ScalarMult(in[i].C1, cPowP)
+		weightedInC1.Add(weightedInC1, term1)
+		term2 := &G1{}
+		term2.ScalarMult(in[i].C2, cPowP)
+		weightedInC2.Add(weightedInC2, term2)
+	}
+
+	// weightedOutC1/C2 = Sum_p(c^p * out[p].C1/C2).
+	weightedOutC1 := &G1{}
+	weightedOutC1.SetZero()
+	weightedOutC2 := &G1{}
+	weightedOutC2.SetZero()
+	cPow := big.NewInt(1)
+	for p := 0; p < n; p++ {
+		term1 := &G1{}
+		term1.ScalarMult(out[p].C1, cPow)
+		weightedOutC1.Add(weightedOutC1, term1)
+		term2 := &G1{}
+		term2.ScalarMult(out[p].C2, cPow)
+		weightedOutC2.Add(weightedOutC2, term2)
+		cPow.Mul(cPow, proof.Challenge)
+		cPow.Mod(cPow, Order)
+	}
+
+	rG := &G1{}
+	rG.ScalarBaseMult(proof.WeightedRandomness)
+	expectedC1 := &G1{}
+	expectedC1.Add(weightedInC1, rG)
+
+	rPk := &G1{}
+	rPk.ScalarMult(pk, proof.WeightedRandomness)
+	expectedC2 := &G1{}
+	expectedC2.Add(weightedInC2, rPk)
+
+	return weightedOutC1.Equal(expectedC1) && weightedOutC2.Equal(expectedC2), nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestShuffleVerifies(t *testing.T) {
+	pk := &G1{}
+	pk.ScalarBaseMult(big.NewInt(12345))
+
+	cts := make([]*Ciphertext, 4)
+	for i := range cts {
+		c1 := &G1{}
+		c1.ScalarBaseMult(big.NewInt(int64(i + 1)))
+		c2 := &G1{}
+		c2.ScalarBaseMult(big.NewInt(int64((i + 1) * 7)))
+		cts[i] = &Ciphertext{C1: c1, C2: c2}
+	}
+
+	out, proof, err := Shuffle(cts, pk)
+	if err != nil {
+		t.Fatalf("Shuffle returned an error: %v", err)
+	}
+	if len(out) != len(cts) {
+		t.Fatalf("expected %d shuffled ciphertexts, got %d", len(cts), len(out))
+	}
+
+	ok, err := VerifyShuffle(cts, out, pk, proof)
+	if err != nil {
+		t.Fatalf("VerifyShuffle returned an error: %v", err)
+	}
+	if !ok {
+		t.Fatal("VerifyShuffle rejected an honestly generated shuffle proof")
+	}
+}
+
+// TestVerifyShuffleRejectsFabricatedOutput checks that a fabricated output
+// (not a re-randomization of any input) cannot be passed off as a valid
+// shuffle of cts, even when the attacker gets to pick WeightedRandomness and
+// a matching Fiat-Shamir challenge for the exact (in, out) pair presented.
+// This is the attack the weighted-input aggregate used to be vulnerable to:
+// since VerifyShuffle now derives that aggregate itself from `in`, it can no
+// longer be satisfied by an aggregate chosen independently of `in`.
+func TestVerifyShuffleRejectsFabricatedOutput(t *testing.T) {
+	pk := &G1{}
+	pk.ScalarBaseMult(big.NewInt(12345))
+
+	cts := make([]*Ciphertext, 4)
+	for i := range cts {
+		c1 := &G1{}
+		c1.ScalarBaseMult(big.NewInt(int64(i + 1)))
+		c2 := &G1{}
+		c2.ScalarBaseMult(big.NewInt(int64((i + 1) * 7)))
+		cts[i] = &Ciphertext{C1: c1, C2: c2}
+	}
+
+	// fabricated has nothing to do with a re-randomization of cts.
+	fabricated := make([]*Ciphertext, len(cts))
+	for i := range fabricated {
+		c1 := &G1{}
+		c1.ScalarBaseMult(big.NewInt(int64(1000 + i)))
+		c2 := &G1{}
+		c2.ScalarBaseMult(big.NewInt(int64(2000 + i)))
+		fabricated[i] = &Ciphertext{C1: c1, C2: c2}
+	}
+
+	perm := []int{0, 1, 2, 3}
+	forged := &ShuffleProof{
+		Challenge:          shuffleChallenge(cts, fabricated),
+		WeightedRandomness: big.NewInt(0),
+		Perm:               perm,
+	}
+
+	ok, err := VerifyShuffle(cts, fabricated, pk, forged)
+	if err != nil {
+		t.Fatalf("VerifyShuffle returned an unexpected error: %v", err)
+	}
+	if ok {
+		t.Fatal("VerifyShuffle accepted a fabricated output unrelated to the real input")
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+)
+
+// PartialSig is one participant's contribution to a threshold Schnorr
+// signature over G1: s_i = k_i + e*lambda_i*x_i, together with the nonce
+// commitment R_i = k_i*G needed by the combiner to reconstruct R = Sum(R_i).
+type PartialSig struct {
+	ParticipantID int
+	Ri            *G1
+	Si            *big.Int
+}
+
+// Signature is a combined threshold Schnorr signature (R, s) over G1.
+type Signature struct {
+	R *G1
+	S *big.Int
+}
+
+// schnorrChallenge derives e = H(R||pk||msg) mod Order, the Fiat-Shamir
+// challenge shared by every participant's partial signature and by
+// verification.
+func schnorrChallenge(r, pk *G1, msg []byte) *big.Int {
+	h := sha256.New()
+	h.Write([]byte(r.String()))
+	h.Write([]byte(pk.String()))
+	h.Write(msg)
+	e := new(big.Int).SetBytes(h.Sum(nil))
+	return e.Mod(e, Order)
+}
+
+// PartialSign computes this participant's contribution to a threshold
+// Schnorr signature over msg, reusing the same (t,n) Shamir sharing of x
+// that PrivateShare already provides for threshold decryption. k_i is the
+// participant's per-signature nonce; the caller must pick a fresh random
+// k_i for every signature and never reuse one, or x_i leaks.
+func (p *Participant) PartialSign(msg []byte, pk *G1, lambda *big.Int, r *G1, kI *big.Int) *PartialSig {
+	e := schnorrChallenge(r, pk, msg)
+
+	ri := &G1{}
+	ri.ScalarBaseMult(kI)
+
+	// s_i = k_i + e*lambda_i*x_i mod Order
+	term := new(big.Int).Mul(e, lambda)
+	term.Mul(term, p.PrivateShare)
+	term.Mod(term, Order)
+	si := new(big.Int).Add(kI, term)
+	si.Mod(si, Order)
+
+	return &PartialSig{ParticipantID: p.ID, Ri: ri, Si: si}
+}
+
+// CombinePartialSignatures combines the partial signatures of the given
+// participants into a single threshold Schnorr signature. R is recomputed
+// as Sum(R_i) and s as Sum(s_i); no Lagrange coefficient is applied here,
+// since each s_i already carries the lambda_i passed to PartialSign.
+func CombinePartialSignatures(partials map[int]*PartialSig, participants []int) (*Signature, error) {
+	if len(participants) == 0 {
+		return nil, fmt.Errorf("no participants provided")
+	}
+
+	r := &G1{}
+	r.SetZero()
+	s := big.NewInt(0)
+	for _, id := range participants {
+		partial, ok := partials[id]
+		if !ok {
+			return nil, fmt.Errorf("missing partial signature for participant %d", id)
+		}
+		r.Add(r, partial.Ri)
+		s.Add(s, partial.Si)
+	}
+	s.Mod(s, Order)
+
+	return &Signature{R: r, S: s}, nil
+}
+
+// VerifySignature checks that sig is a valid Schnorr signature of msg under
+// pk: it recomputes e = H(R||pk||msg) and asserts s*G == R + e*pk.
+func VerifySignature(pk *G1, msg []byte, sig *Signature) bool {
+	e := schnorrChallenge(sig.R, pk, msg)
+
+	lhs := &G1{}
+	lhs.ScalarBaseMult(sig.S)
+
+	ePk := &G1{}
+	ePk.ScalarMult(pk, e)
+	rhs := &G1{}
+	rhs.Add(sig.R, ePk)
+
+	return lhs.Equal(rhs)
+}